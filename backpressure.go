@@ -0,0 +1,166 @@
+package mcp
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DropPolicy decides what happens when a connection's outbound queue fills
+// up faster than the client can drain it.
+type DropPolicy int
+
+const (
+	// DropOldest discards the queue's oldest unsent frame to make room for
+	// the new one.
+	DropOldest DropPolicy = iota
+	// DropNewest discards the frame that just failed to enqueue, keeping
+	// everything already queued.
+	DropNewest
+	// BlockProducer makes the producer wait until the client drains the
+	// queue (or the connection closes).
+	BlockProducer
+	// CloseSlowClient closes the connection with code 1008 ("slow
+	// consumer") instead of dropping frames.
+	CloseSlowClient
+)
+
+// StreamingConfig bounds how much a slow WebSocket client can make a
+// streaming tool's producer goroutine (or the connection itself) block or
+// buffer.
+type StreamingConfig struct {
+	// PerClientOutboundQueue is the size of each connection's outbound
+	// frame buffer.
+	PerClientOutboundQueue int
+
+	// DropPolicy governs what happens once that buffer is full.
+	DropPolicy DropPolicy
+
+	// MaxStreamsPerConn caps how many "stream/subscribe" subscriptions one
+	// connection may have active at once. Zero means unbounded. Streaming
+	// tools called via tools/call under FramingJSONRPC2 are not counted
+	// against this cap.
+	MaxStreamsPerConn int
+}
+
+// DefaultStreamingConfig returns sane defaults for streaming backpressure.
+func DefaultStreamingConfig() StreamingConfig {
+	return StreamingConfig{
+		PerClientOutboundQueue: 64,
+		DropPolicy:             DropOldest,
+	}
+}
+
+// WebSocketMetrics tracks streaming counters for observability, summed over
+// every connection of the WebSocket transport. StreamsActive counts live
+// "stream/subscribe" subscriptions, StreamsDropped counts outbound frames
+// discarded by DropOldest/DropNewest, and BytesOut counts bytes written by
+// the write pumps.
+type WebSocketMetrics struct {
+	streamsActive  int64
+	streamsDropped int64
+	bytesOut       int64
+}
+
+func (m *WebSocketMetrics) StreamsActive() int64  { return atomic.LoadInt64(&m.streamsActive) }
+func (m *WebSocketMetrics) StreamsDropped() int64 { return atomic.LoadInt64(&m.streamsDropped) }
+func (m *WebSocketMetrics) BytesOut() int64       { return atomic.LoadInt64(&m.bytesOut) }
+
+// GetWebSocketMetrics returns the live counters for the WebSocket
+// transport, or nil if EnableWebSocket hasn't been called.
+func (s *Server) GetWebSocketMetrics() *WebSocketMetrics {
+	return s.wsMetrics
+}
+
+type outboundFrame struct {
+	frameType int
+	data      []byte
+}
+
+// enqueue applies cfg.DropPolicy to place frame on the connection's
+// outbound queue, consumed by the dedicated write pump goroutine.
+func (w *wsConn) enqueue(frame outboundFrame) {
+	select {
+	case w.outbound <- frame:
+		return
+	case <-w.closed:
+		return
+	default:
+	}
+
+	switch w.streamingCfg.DropPolicy {
+	case BlockProducer:
+		select {
+		case w.outbound <- frame:
+		case <-w.closed:
+		}
+
+	case CloseSlowClient:
+		w.closeSlow()
+
+	case DropNewest:
+		w.recordDrop()
+
+	default: // DropOldest
+		select {
+		case <-w.outbound:
+			w.recordDrop()
+		default:
+		}
+		select {
+		case w.outbound <- frame:
+		default:
+		}
+	}
+}
+
+func (w *wsConn) recordDrop() {
+	if w.metrics != nil {
+		atomic.AddInt64(&w.metrics.streamsDropped, 1)
+	}
+}
+
+// closeSlow closes the connection with code 1008 ("slow consumer").
+func (w *wsConn) closeSlow() {
+	w.shutdown(true, 1008, "slow consumer")
+}
+
+// shutdown tears the connection down exactly once: it unblocks any
+// enqueue/Read waiting on closed and stops the write pump, and optionally
+// sends a WebSocket close frame first. WriteControl is safe to call
+// concurrently with the write pump's WriteMessage calls.
+//
+// It deliberately never closes outbound: enqueue and runWritePump are the
+// only things that touch that channel, and both already select on closed,
+// so leaving outbound open means a producer racing this shutdown can never
+// panic with a send on a closed channel.
+func (w *wsConn) shutdown(sendClose bool, code int, reason string) {
+	w.closeOnce.Do(func() {
+		close(w.closed)
+		if sendClose {
+			msg := websocket.FormatCloseMessage(code, reason)
+			_ = w.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		}
+		w.conn.Close()
+	})
+}
+
+// runWritePump is the connection's sole writer: gorilla permits only one
+// concurrent WriteMessage caller, so every write (responses, pings, stream
+// updates) is funneled through this goroutine's queue.
+func (w *wsConn) runWritePump() {
+	for {
+		select {
+		case frame := <-w.outbound:
+			if err := w.conn.WriteMessage(frame.frameType, frame.data); err != nil {
+				return
+			}
+			if w.metrics != nil {
+				atomic.AddInt64(&w.metrics.bytesOut, int64(len(frame.data)))
+			}
+		case <-w.closed:
+			return
+		}
+	}
+}
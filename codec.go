@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Codec marshals and unmarshals WebSocket (and, for the SSE transport,
+// per-event) payloads. The core module only ships the JSON codec; binary
+// codecs (msgpack, CBOR) are opt-in via build tag so the default dependency
+// footprint stays small.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	ContentType() string
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) ContentType() string                { return "application/json" }
+
+// codecRegistration pairs a Codec with the subprotocol clients request it by
+// and whether it needs a binary (as opposed to text) WebSocket frame.
+type codecRegistration struct {
+	codec  Codec
+	binary bool
+}
+
+var (
+	codecMu       sync.RWMutex
+	codecRegistry = map[string]codecRegistration{
+		SubprotocolJSON: {codec: jsonCodec{}, binary: false},
+	}
+)
+
+// Subprotocols advertised for WebSocket codec negotiation. Binary codecs
+// register themselves under these via RegisterCodec from a build-tagged
+// file (see codec_msgpack.go, codec_cbor.go).
+const (
+	SubprotocolJSON    = "mcp.json.v1"
+	SubprotocolMsgpack = "mcp.msgpack.v1"
+	SubprotocolCBOR    = "mcp.cbor.v1"
+)
+
+// RegisterCodec makes a Codec available under subprotocol, both for
+// WebSocket subprotocol negotiation and for the SSE transport's ?codec=
+// query parameter, which looks codecs up by the same key. A codec that
+// should be selectable by a short name (e.g. "cbor", "msgpack") is
+// registered a second time under that name.
+func RegisterCodec(subprotocol string, codec Codec, binary bool) {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	codecRegistry[subprotocol] = codecRegistration{codec: codec, binary: binary}
+}
+
+func getCodec(subprotocol string) (codecRegistration, bool) {
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	reg, ok := codecRegistry[subprotocol]
+	return reg, ok
+}
+
+// registeredSubprotocols lists every subprotocol with a registered codec,
+// for advertising to clients during the WebSocket upgrade.
+func registeredSubprotocols() []string {
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	out := make([]string, 0, len(codecRegistry))
+	for name := range codecRegistry {
+		out = append(out, name)
+	}
+	return out
+}
@@ -0,0 +1,16 @@
+//go:build cbor
+
+package mcp
+
+import "github.com/fxamacker/cbor/v2"
+
+type cborCodec struct{}
+
+func (cborCodec) Marshal(v any) ([]byte, error)      { return cbor.Marshal(v) }
+func (cborCodec) Unmarshal(data []byte, v any) error { return cbor.Unmarshal(data, v) }
+func (cborCodec) ContentType() string                { return "application/cbor" }
+
+func init() {
+	RegisterCodec(SubprotocolCBOR, cborCodec{}, true)
+	RegisterCodec("cbor", cborCodec{}, true)
+}
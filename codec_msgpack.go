@@ -0,0 +1,16 @@
+//go:build msgpack
+
+package mcp
+
+import "github.com/vmihailenco/msgpack/v5"
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v any) ([]byte, error)      { return msgpack.Marshal(v) }
+func (msgpackCodec) Unmarshal(data []byte, v any) error { return msgpack.Unmarshal(data, v) }
+func (msgpackCodec) ContentType() string                { return "application/msgpack" }
+
+func init() {
+	RegisterCodec(SubprotocolMsgpack, msgpackCodec{}, true)
+	RegisterCodec("msgpack", msgpackCodec{}, true)
+}
@@ -0,0 +1,4 @@
+// Package mcp implements a small SDK for building Model Context Protocol
+// servers, including optional HTTP-based transports (Server-Sent Events and
+// WebSocket) for clients that cannot speak stdio JSON-RPC directly.
+package mcp
@@ -0,0 +1,5 @@
+package mcp
+
+import "errors"
+
+var errUnauthorized = errors.New("mcp: unauthorized")
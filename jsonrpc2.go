@@ -0,0 +1,273 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// FramingMode selects the wire framing used to interpret text frames on a
+// WebSocket connection.
+type FramingMode int
+
+const (
+	// FramingCustom is the SDK's bespoke type/id/method/params envelope
+	// (WebSocketMessage).
+	FramingCustom FramingMode = iota
+
+	// FramingJSONRPC2 speaks JSON-RPC 2.0, including batched requests and
+	// $/cancel notifications, matching the framing MCP uses over stdio.
+	FramingJSONRPC2
+)
+
+// SubprotocolJSONRPC2 is the Sec-WebSocket-Protocol value clients negotiate
+// to select FramingJSONRPC2 without changing WebSocketConfig.Framing.
+const SubprotocolJSONRPC2 = "mcp.jsonrpc.v2"
+
+type jsonrpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type jsonrpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonrpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *jsonrpcError   `json:"error,omitempty"`
+}
+
+type jsonrpcNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+func isJSONRPC2Notification(id json.RawMessage) bool {
+	return len(id) == 0 || string(id) == "null"
+}
+
+// parseJSONRPC2Batch accepts either a single JSON-RPC object or an array of
+// them, per the JSON-RPC 2.0 batch extension.
+func parseJSONRPC2Batch(data []byte) (items []json.RawMessage, wasBatch bool, err error) {
+	trimmed := json.RawMessage(data)
+	var arr []json.RawMessage
+	if err := json.Unmarshal(trimmed, &arr); err == nil {
+		return arr, true, nil
+	}
+	return []json.RawMessage{trimmed}, false, nil
+}
+
+func jsonrpc2Error(id json.RawMessage, code int, message string) *jsonrpcResponse {
+	return &jsonrpcResponse{JSONRPC: "2.0", ID: id, Error: &jsonrpcError{Code: code, Message: message}}
+}
+
+// handleJSONRPC2Frame processes one text frame under FramingJSONRPC2: it may
+// contain a single request or a batch, runs each concurrently on a bounded
+// worker pool, and writes one joined response frame (skipping notifications).
+func (s *Server) handleJSONRPC2Frame(ctx context.Context, conn *wsConn, info *ConnInfo, data []byte) {
+	items, wasBatch, err := parseJSONRPC2Batch(data)
+	if err != nil {
+		conn.writeJSON(jsonrpc2Error(nil, -32700, "Parse error"))
+		return
+	}
+
+	maxConcurrency := s.wsConfig.MaxBatchConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = 8
+	}
+	sem := make(chan struct{}, maxConcurrency)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var responses []interface{}
+
+	for _, raw := range items {
+		raw := raw
+		var req jsonrpcRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			mu.Lock()
+			responses = append(responses, jsonrpc2Error(nil, -32600, "Invalid Request"))
+			mu.Unlock()
+			continue
+		}
+
+		if req.Method == "$/cancel" {
+			s.handleJSONRPC2Cancel(info, req.Params)
+			continue
+		}
+
+		notification := isJSONRPC2Notification(req.ID)
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			resp := s.handleJSONRPC2Request(ctx, conn, info, req, notification)
+			if resp == nil {
+				return
+			}
+			mu.Lock()
+			responses = append(responses, resp)
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	if len(responses) == 0 {
+		return
+	}
+	if !wasBatch && len(responses) == 1 {
+		conn.writeJSON(responses[0])
+		return
+	}
+	conn.writeJSON(responses)
+}
+
+func (s *Server) handleJSONRPC2Cancel(info *ConnInfo, params json.RawMessage) {
+	var body struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(params, &body); err != nil {
+		return
+	}
+	info.cancelJSONRPC2(string(body.ID))
+}
+
+func (s *Server) handleJSONRPC2Request(ctx context.Context, conn *wsConn, info *ConnInfo, req jsonrpcRequest, notification bool) *jsonrpcResponse {
+	if req.Method != "tools/call" {
+		if notification {
+			return nil
+		}
+		return jsonrpc2Error(req.ID, -32601, "Method not found")
+	}
+
+	var call struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(req.Params, &call); err != nil {
+		if notification {
+			return nil
+		}
+		return jsonrpc2Error(req.ID, -32602, "Invalid params")
+	}
+
+	reqCtx, cancel := context.WithCancel(ctx)
+	idKey := string(req.ID)
+	if idKey != "" {
+		info.registerJSONRPC2Cancel(idKey, cancel)
+	}
+
+	if s.hasStreamingTool(call.Name) {
+		results, err := s.CallStreamingTool(reqCtx, call.Name, call.Arguments)
+		if err != nil {
+			cancel()
+			if idKey != "" {
+				info.unregisterJSONRPC2Cancel(idKey)
+			}
+			if notification {
+				return nil
+			}
+			return jsonrpc2Error(req.ID, -32000, err.Error())
+		}
+		// streamJSONRPC2Progress runs for the life of the stream, so cancel
+		// (and the $/cancel registration backing it) must outlive this
+		// synchronous dispatch call; ownership of both moves into the
+		// goroutine instead of being deferred here.
+		go func() {
+			defer cancel()
+			if idKey != "" {
+				defer info.unregisterJSONRPC2Cancel(idKey)
+			}
+			s.streamJSONRPC2Progress(reqCtx, conn, req.ID, results)
+		}()
+		if notification {
+			return nil
+		}
+		return &jsonrpcResponse{JSONRPC: "2.0", ID: req.ID, Result: map[string]interface{}{"subscribed": true}}
+	}
+
+	defer cancel()
+	if idKey != "" {
+		defer info.unregisterJSONRPC2Cancel(idKey)
+	}
+
+	result, err := s.CallTool(reqCtx, call.Name, call.Arguments)
+	if notification {
+		return nil
+	}
+	if err != nil {
+		return jsonrpc2Error(req.ID, -32000, err.Error())
+	}
+	return &jsonrpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func (s *Server) hasStreamingTool(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.streamingTools[name]
+	return ok
+}
+
+func (s *Server) streamJSONRPC2Progress(ctx context.Context, conn *wsConn, id json.RawMessage, results <-chan StreamingResult) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case result, ok := <-results:
+			if !ok {
+				return
+			}
+			conn.writeJSON(jsonrpcNotification{
+				JSONRPC: "2.0",
+				Method:  "$/progress",
+				Params: map[string]interface{}{
+					"id":       json.RawMessage(id),
+					"progress": result.Progress,
+					"data":     result.Data,
+					"finished": result.Finished,
+				},
+			})
+			if result.Finished {
+				return
+			}
+		}
+	}
+}
+
+// registerJSONRPC2Cancel/cancelJSONRPC2/unregisterJSONRPC2Cancel back the
+// $/cancel notification: they let an in-flight tools/call's context be
+// canceled by a later message on the same connection carrying the same id.
+func (c *ConnInfo) registerJSONRPC2Cancel(idKey string, cancel context.CancelFunc) {
+	c.rpcMu.Lock()
+	defer c.rpcMu.Unlock()
+	if c.rpcCancels == nil {
+		c.rpcCancels = make(map[string]context.CancelFunc)
+	}
+	c.rpcCancels[idKey] = cancel
+}
+
+func (c *ConnInfo) unregisterJSONRPC2Cancel(idKey string) {
+	c.rpcMu.Lock()
+	defer c.rpcMu.Unlock()
+	delete(c.rpcCancels, idKey)
+}
+
+func (c *ConnInfo) cancelJSONRPC2(idKey string) {
+	c.rpcMu.Lock()
+	cancel, ok := c.rpcCancels[idKey]
+	c.rpcMu.Unlock()
+	if ok {
+		cancel()
+	}
+}
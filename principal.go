@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+)
+
+// Principal identifies whoever authenticated a WebSocket connection.
+type Principal interface {
+	// Name returns a human-readable identifier for the principal, e.g. a
+	// subject claim, client certificate CN, or API key label.
+	Name() string
+}
+
+// Authenticator validates an incoming WebSocket upgrade request and returns
+// the Principal it authenticates as. Implementations can plug in JWT,
+// mTLS client-certificate, or HMAC-signed request auth in place of the
+// built-in static bearer token check.
+type Authenticator interface {
+	Authenticate(r *http.Request) (Principal, error)
+}
+
+// tokenPrincipal is the Principal produced by the built-in static bearer
+// token check (WebConfig.AuthToken) when no Authenticator is configured.
+type tokenPrincipal struct{ token string }
+
+// Name deliberately doesn't embed the bearer token: Principal.Name is meant
+// for handlers and audit logging, and every caller logging or displaying it
+// would otherwise get a ready-made credential leak.
+func (p tokenPrincipal) Name() string { return "token-auth" }
+
+type principalContextKey struct{}
+
+// PrincipalFromContext returns the Principal attached to ctx by the
+// WebSocket transport, if any.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalContextKey{}).(Principal)
+	return p, ok
+}
+
+func contextWithPrincipal(ctx context.Context, p Principal) context.Context {
+	if p == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, principalContextKey{}, p)
+}
@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// ToolHandler handles a single tool invocation. params is the raw JSON
+// "arguments" object from the request; implementations are expected to
+// unmarshal it into whatever shape they need.
+type ToolHandler func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+type toolEntry struct {
+	name        string
+	description string
+	handler     ToolHandler
+}
+
+// Server is an MCP server instance. It owns the registered tools and,
+// optionally, the HTTP-based transports (SSE, WebSocket) layered on top of
+// them.
+type Server struct {
+	name    string
+	version string
+
+	mu             sync.RWMutex
+	tools          map[string]*toolEntry
+	streamingTools map[string]*streamingToolEntry
+
+	webMu      sync.Mutex
+	webConfig  WebConfig
+	mux        *http.ServeMux
+	httpServer *http.Server
+	listener   net.Listener
+
+	sseManager *SSEManager
+	wsConfig   WebSocketConfig
+	wsMetrics  *WebSocketMetrics
+	tunnels    map[string]TunnelHandler
+}
+
+// NewServer creates an MCP server with the given name and version. Tools are
+// registered on it via Tool/StreamingTool before any transport is started.
+func NewServer(name, version string) *Server {
+	return &Server{
+		name:           name,
+		version:        version,
+		tools:          make(map[string]*toolEntry),
+		streamingTools: make(map[string]*streamingToolEntry),
+		tunnels:        make(map[string]TunnelHandler),
+	}
+}
+
+// Tool registers a request/response tool under name.
+func (s *Server) Tool(name, description string, handler ToolHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tools[name] = &toolEntry{name: name, description: description, handler: handler}
+}
+
+// CallTool invokes a previously registered tool by name.
+func (s *Server) CallTool(ctx context.Context, name string, params json.RawMessage) (interface{}, error) {
+	s.mu.RLock()
+	entry, ok := s.tools[name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown tool: %s", name)
+	}
+	return entry.handler(ctx, params)
+}
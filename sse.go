@@ -0,0 +1,304 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SSEConfig configures the Server-Sent Events transport.
+type SSEConfig struct {
+	// HeartbeatInterval is how often a comment-only keepalive is sent to
+	// idle connections so intermediate proxies don't time them out.
+	HeartbeatInterval time.Duration
+
+	// ReplayBufferSize is how many past events the manager keeps around so
+	// that a reconnecting client (one sending Last-Event-ID) can catch up
+	// on whatever it missed. Zero disables replay.
+	ReplayBufferSize int
+}
+
+// DefaultSSEConfig returns sane defaults for the SSE transport.
+func DefaultSSEConfig() SSEConfig {
+	return SSEConfig{
+		HeartbeatInterval: 15 * time.Second,
+		ReplayBufferSize:  256,
+	}
+}
+
+// SSEEvent is a single event broadcast to connected SSE clients.
+type SSEEvent struct {
+	ID    string      `json:"id,omitempty"`
+	Event string      `json:"event,omitempty"`
+	Data  interface{} `json:"data"`
+}
+
+// bufferedSSEEvent pairs an event with the manager's internal monotonic
+// sequence number, which is what actually gets sent as the wire "id:" field
+// and what Last-Event-ID is matched against. This lets callers supply
+// arbitrary, non-ordered string IDs on SSEEvent without breaking replay.
+type bufferedSSEEvent struct {
+	seq   int64
+	event SSEEvent
+}
+
+type sseClient struct {
+	ch   chan bufferedSSEEvent
+	done chan struct{}
+}
+
+// SSEManager tracks connected SSE clients and the replay buffer used to
+// serve reconnecting clients.
+type SSEManager struct {
+	cfg SSEConfig
+
+	mu      sync.RWMutex
+	seq     int64
+	buffer  []bufferedSSEEvent
+	clients map[*sseClient]struct{}
+}
+
+func newSSEManager(cfg SSEConfig) *SSEManager {
+	if cfg.HeartbeatInterval <= 0 {
+		cfg.HeartbeatInterval = DefaultSSEConfig().HeartbeatInterval
+	}
+	return &SSEManager{
+		cfg:     cfg,
+		clients: make(map[*sseClient]struct{}),
+	}
+}
+
+// parseLastEventID interprets a Last-Event-ID value as the internal sequence
+// number it was issued as. An empty or unrecognized value means "replay
+// everything buffered".
+func parseLastEventID(lastEventID string) int64 {
+	if lastEventID == "" {
+		return 0
+	}
+	v, err := strconv.ParseInt(lastEventID, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// EventsSince returns every buffered event with a sequence number strictly
+// greater than the one identified by lastEventID. An empty or unrecognized
+// lastEventID replays the entire buffer.
+func (m *SSEManager) EventsSince(lastEventID string) []SSEEvent {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	after := parseLastEventID(lastEventID)
+	var out []SSEEvent
+	for _, b := range m.buffer {
+		if b.seq > after {
+			out = append(out, b.event)
+		}
+	}
+	return out
+}
+
+func (m *SSEManager) broadcast(event SSEEvent) {
+	m.mu.Lock()
+	m.seq++
+	buffered := bufferedSSEEvent{seq: m.seq, event: event}
+	if m.cfg.ReplayBufferSize > 0 {
+		m.buffer = append(m.buffer, buffered)
+		if len(m.buffer) > m.cfg.ReplayBufferSize {
+			m.buffer = m.buffer[len(m.buffer)-m.cfg.ReplayBufferSize:]
+		}
+	}
+	clients := make([]*sseClient, 0, len(m.clients))
+	for c := range m.clients {
+		clients = append(clients, c)
+	}
+	m.mu.Unlock()
+
+	for _, c := range clients {
+		select {
+		case c.ch <- buffered:
+			continue
+		case <-c.done:
+			continue
+		default:
+		}
+
+		// c.ch is full: a stalled consumer would otherwise make every
+		// future broadcast block on it indefinitely. Drop its oldest
+		// queued event to make room, the same DropOldest behavior the
+		// WebSocket transport's enqueue uses by default.
+		select {
+		case <-c.ch:
+		default:
+		}
+		select {
+		case c.ch <- buffered:
+		default:
+		}
+	}
+}
+
+// register adds a client to the broadcast set and, in the same locked
+// section, snapshots the replay tail for lastEventID and the manager's
+// current sequence number. Doing both atomically is what prevents an event
+// broadcast in the gap between "take the replay snapshot" and "start
+// listening on the client's channel" from being delivered twice: any
+// broadcast either lands in this snapshot (and so is excluded from future
+// channel sends, since the client wasn't registered yet when it fired) or
+// is sent on the channel (and so is excluded from this snapshot, since it
+// hadn't happened yet when the snapshot was taken).
+func (m *SSEManager) register(lastEventID string) (client *sseClient, currentSeq int64, replay []bufferedSSEEvent) {
+	c := &sseClient{ch: make(chan bufferedSSEEvent, 16), done: make(chan struct{})}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.clients[c] = struct{}{}
+	currentSeq = m.seq
+
+	if lastEventID != "" {
+		after := parseLastEventID(lastEventID)
+		for _, b := range m.buffer {
+			if b.seq > after {
+				replay = append(replay, b)
+			}
+		}
+	}
+
+	return c, currentSeq, replay
+}
+
+func (m *SSEManager) unregister(c *sseClient) {
+	m.mu.Lock()
+	delete(m.clients, c)
+	m.mu.Unlock()
+	close(c.done)
+}
+
+// EnableSSE mounts the SSE transport's /events endpoint on the web
+// transport's mux. EnableWebTransport must be called first.
+func (s *Server) EnableSSE(cfg SSEConfig) {
+	s.webMu.Lock()
+	s.sseManager = newSSEManager(cfg)
+	s.mux.HandleFunc("/events", s.handleSSE)
+	s.webMu.Unlock()
+}
+
+// GetSSEManager returns the manager backing the SSE transport, or nil if
+// EnableSSE hasn't been called.
+func (s *Server) GetSSEManager() *SSEManager {
+	return s.sseManager
+}
+
+// BroadcastSSEEvent sends event to every connected SSE client and appends it
+// to the replay buffer. It is a no-op if EnableSSE hasn't been called.
+func (s *Server) BroadcastSSEEvent(event SSEEvent) {
+	if s.sseManager == nil {
+		return
+	}
+	s.sseManager.broadcast(event)
+}
+
+// writeSSEEvent marshals event.Data with codec. Binary codecs can't be
+// embedded in a single-line "data:" field as-is, so their output is
+// base64-encoded first.
+func writeSSEEvent(w http.ResponseWriter, flusher http.Flusher, seq int64, event SSEEvent, codec Codec, base64Encode bool) error {
+	raw, err := codec.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	payload := raw
+	if base64Encode {
+		payload = []byte(base64.StdEncoding.EncodeToString(raw))
+	}
+
+	if _, err := fmt.Fprintf(w, "id: %d\n", seq); err != nil {
+		return err
+	}
+	if event.Event != "" {
+		if _, err := fmt.Fprintf(w, "event: %s\n", event.Event); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "data: %s\n\n", payload); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
+
+func (s *Server) handleSSE(w http.ResponseWriter, r *http.Request) {
+	if !s.checkAuth(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	codec := Codec(jsonCodec{})
+	base64Encode := false
+	contentType := "text/event-stream"
+	if codecName := r.URL.Query().Get("codec"); codecName != "" {
+		if reg, ok := getCodec(codecName); ok {
+			codec = reg.codec
+			base64Encode = reg.binary
+			contentType = reg.codec.ContentType()
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	// Tell EventSource clients how long to wait before auto-reconnecting.
+	fmt.Fprintf(w, "retry: %d\n", 3000)
+
+	manager := s.sseManager
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+
+	client, currentSeq, replay := manager.register(lastEventID)
+	defer manager.unregister(client)
+
+	connected := SSEEvent{Event: "connected", Data: map[string]interface{}{"status": "connected"}}
+	if err := writeSSEEvent(w, flusher, currentSeq, connected, codec, base64Encode); err != nil {
+		return
+	}
+
+	for _, b := range replay {
+		if err := writeSSEEvent(w, flusher, b.seq, b.event, codec, base64Encode); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(manager.cfg.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case buffered := <-client.ch:
+			if err := writeSSEEvent(w, flusher, buffered.seq, buffered.event, codec, base64Encode); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
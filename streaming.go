@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Progress describes how far a streaming tool has gotten.
+type Progress struct {
+	Current int64  `json:"current"`
+	Total   int64  `json:"total"`
+	Message string `json:"message,omitempty"`
+}
+
+// NewProgress builds a Progress value for the given current/total counters.
+func NewProgress(current, total int64, message string) Progress {
+	return Progress{Current: current, Total: total, Message: message}
+}
+
+// StreamingResult is one item emitted by a streaming tool. Finished marks the
+// last item in the stream.
+type StreamingResult struct {
+	Data     interface{} `json:"data"`
+	Progress Progress    `json:"progress"`
+	Finished bool        `json:"finished"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// StreamingToolHandler starts a streaming tool invocation and returns a
+// channel of results. The handler must close the channel when done and must
+// stop producing once ctx is canceled.
+type StreamingToolHandler func(ctx context.Context, params json.RawMessage) (<-chan StreamingResult, error)
+
+type streamingToolEntry struct {
+	name        string
+	description string
+	handler     StreamingToolHandler
+}
+
+// StreamingTool registers a streaming tool under name.
+func (s *Server) StreamingTool(name, description string, handler StreamingToolHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streamingTools[name] = &streamingToolEntry{name: name, description: description, handler: handler}
+}
+
+// CallStreamingTool invokes a previously registered streaming tool by name.
+func (s *Server) CallStreamingTool(ctx context.Context, name string, params json.RawMessage) (<-chan StreamingResult, error) {
+	s.mu.RLock()
+	entry, ok := s.streamingTools[name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown streaming tool: %s", name)
+	}
+	return entry.handler(ctx, params)
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcp "github.com/can-acar/jarvis-mcp-sdk"
+)
+
+// fastStreamingTool registers a streaming tool that emits results as fast as
+// possible until ctx is canceled, which is what makes a slow/absent reader
+// actually exercise backpressure.
+func fastStreamingTool(server *mcp.Server, name string) {
+	server.StreamingTool(name, "emits as fast as possible", func(ctx context.Context, params json.RawMessage) (<-chan mcp.StreamingResult, error) {
+		out := make(chan mcp.StreamingResult, 1)
+		go func() {
+			defer close(out)
+			for i := 0; ; i++ {
+				select {
+				case <-ctx.Done():
+					return
+				case out <- mcp.StreamingResult{
+					Data:     fmt.Sprintf("item %d", i),
+					Progress: mcp.NewProgress(int64(i), -1, ""),
+				}:
+				}
+			}
+		}()
+		return out, nil
+	})
+}
+
+// TestWebSocketSubscribeThenCloseDoesNotPanic is a regression test: a client
+// that subscribes to a fast streaming tool and immediately disconnects used
+// to crash the whole process with "send on closed channel" in
+// (*wsConn).enqueue racing shutdown's close(w.outbound).
+func TestWebSocketSubscribeThenCloseDoesNotPanic(t *testing.T) {
+	server := mcp.NewServer("backpressure-panic-test", "1.0.0")
+	fastStreamingTool(server, "fast")
+
+	server.EnableWebTransport(mcp.WebConfig{Port: 8097, Host: "localhost"})
+	server.EnableWebSocket(mcp.DefaultWebSocketConfig())
+
+	require.NoError(t, server.StartWebTransport())
+	time.Sleep(100 * time.Millisecond)
+	defer func() { assert.NoError(t, server.StopWebTransport()) }()
+
+	u := url.URL{Scheme: "ws", Host: "localhost:8097", Path: "/ws"}
+
+	for i := 0; i < 200; i++ {
+		c, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+		require.NoError(t, err)
+
+		subscribeMsg := mcp.WebSocketMessage{
+			Type:   "request",
+			ID:     fmt.Sprintf("sub-%d", i),
+			Method: "stream/subscribe",
+			Params: json.RawMessage(`{"tool": "fast", "arguments": {}}`),
+		}
+		require.NoError(t, c.WriteJSON(subscribeMsg))
+		c.Close()
+	}
+
+	// If the server survived the loop above without panicking, give any
+	// still-running producer goroutines a moment to observe the closed
+	// connection and exit cleanly.
+	time.Sleep(100 * time.Millisecond)
+}
+
+// TestWebSocketCloseSlowClient verifies the CloseSlowClient drop policy
+// closes a connection whose outbound queue fills up, with close code 1008
+// ("slow consumer"), instead of blocking the producer or dropping frames.
+func TestWebSocketCloseSlowClient(t *testing.T) {
+	server := mcp.NewServer("backpressure-closeslow-test", "1.0.0")
+	fastStreamingTool(server, "fast")
+
+	wsConfig := mcp.DefaultWebSocketConfig()
+	wsConfig.Streaming.PerClientOutboundQueue = 4
+	wsConfig.Streaming.DropPolicy = mcp.CloseSlowClient
+	server.EnableWebTransport(mcp.WebConfig{Port: 8098, Host: "localhost"})
+	server.EnableWebSocket(wsConfig)
+
+	require.NoError(t, server.StartWebTransport())
+	time.Sleep(100 * time.Millisecond)
+	defer func() { assert.NoError(t, server.StopWebTransport()) }()
+
+	u := url.URL{Scheme: "ws", Host: "localhost:8098", Path: "/ws"}
+	c, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	require.NoError(t, err)
+	defer c.Close()
+
+	subscribeMsg := mcp.WebSocketMessage{
+		Type:   "request",
+		ID:     "sub-slow",
+		Method: "stream/subscribe",
+		Params: json.RawMessage(`{"tool": "fast", "arguments": {}}`),
+	}
+	require.NoError(t, c.WriteJSON(subscribeMsg))
+
+	// The fast producer fills the 4-slot outbound queue (subscription
+	// response plus stream_update frames) faster than the write pump drains
+	// it. Read until the connection errors out; the server should have
+	// closed it with 1008 rather than block the producer.
+	closedWithSlowConsumer := false
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		c.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+		if _, _, err := c.ReadMessage(); err != nil {
+			if ce, ok := err.(*websocket.CloseError); ok {
+				assert.Equal(t, 1008, ce.Code)
+				closedWithSlowConsumer = true
+			}
+			break
+		}
+	}
+
+	assert.True(t, closedWithSlowConsumer, "expected connection to be closed for being a slow consumer")
+
+	metrics := server.GetWebSocketMetrics()
+	require.NotNil(t, metrics)
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcp "github.com/can-acar/jarvis-mcp-sdk"
+)
+
+type jsonrpcMsg struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+// TestJSONRPC2StreamingProgressAndCancel is a regression test for
+// handleJSONRPC2Request canceling a streaming tool's context before
+// streamJSONRPC2Progress could deliver anything: the context used to be
+// canceled by a defer in the synchronous dispatch function, which returns
+// immediately after starting the async forwarder goroutine.
+func TestJSONRPC2StreamingProgressAndCancel(t *testing.T) {
+	server := mcp.NewServer("jsonrpc2-test", "1.0.0")
+
+	server.StreamingTool("ticker", "emits a tick every 20ms until canceled", func(ctx context.Context, params json.RawMessage) (<-chan mcp.StreamingResult, error) {
+		out := make(chan mcp.StreamingResult, 1)
+		go func() {
+			defer close(out)
+			for i := 0; ; i++ {
+				select {
+				case <-ctx.Done():
+					return
+				case out <- mcp.StreamingResult{Data: fmt.Sprintf("tick %d", i), Progress: mcp.NewProgress(int64(i), -1, "")}:
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+		}()
+		return out, nil
+	})
+
+	server.EnableWebTransport(mcp.WebConfig{Port: 8099, Host: "localhost"})
+	server.EnableWebSocket(mcp.DefaultWebSocketConfig())
+
+	require.NoError(t, server.StartWebTransport())
+	time.Sleep(100 * time.Millisecond)
+	defer func() { assert.NoError(t, server.StopWebTransport()) }()
+
+	dialer := websocket.Dialer{Subprotocols: []string{mcp.SubprotocolJSONRPC2}}
+	u := url.URL{Scheme: "ws", Host: "localhost:8099", Path: "/ws"}
+	c, _, err := dialer.Dial(u.String(), nil)
+	require.NoError(t, err)
+	defer c.Close()
+	assert.Equal(t, mcp.SubprotocolJSONRPC2, c.Subprotocol())
+
+	request := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      "call-1",
+		"method":  "tools/call",
+		"params":  map[string]interface{}{"name": "ticker", "arguments": map[string]interface{}{}},
+	}
+	require.NoError(t, c.WriteJSON(request))
+
+	// The subscription response and the first $/progress notification are
+	// written by independent goroutines, so they can arrive in either
+	// order; read until both the response and two progress notifications
+	// have been seen instead of assuming a fixed order.
+	gotResponse := false
+	progressSeen := 0
+	for !gotResponse || progressSeen < 2 {
+		var msg jsonrpcMsg
+		c.SetReadDeadline(time.Now().Add(2 * time.Second))
+		require.NoError(t, c.ReadJSON(&msg))
+		switch {
+		case msg.Method == "$/progress":
+			progressSeen++
+		case string(msg.ID) == `"call-1"`:
+			require.NotEmpty(t, msg.Result)
+			gotResponse = true
+		}
+	}
+
+	cancelMsg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "$/cancel",
+		"params":  map[string]interface{}{"id": "call-1"},
+	}
+	require.NoError(t, c.WriteJSON(cancelMsg))
+
+	// The ticker tool never sets Finished, so the only way progress stops
+	// is $/cancel actually canceling its context; a short deadline read is
+	// how we confirm that instead of the test itself hanging.
+	c.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	var msg jsonrpcMsg
+	if err := c.ReadJSON(&msg); err == nil {
+		t.Fatalf("expected no further messages after $/cancel, got: %+v", msg)
+	}
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"bufio"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcp "github.com/can-acar/jarvis-mcp-sdk"
+)
+
+// TestSSEBroadcastDoesNotBlockOnSlowClient is a regression test:
+// SSEManager.broadcast used to send to each client's 16-slot channel with
+// no drop path, so one stalled /events consumer (a dead proxy, a paused
+// tab) could block BroadcastSSEEvent server-wide until it unblocked or
+// disconnected. broadcast now drops a full client's oldest queued event
+// instead of blocking on it.
+func TestSSEBroadcastDoesNotBlockOnSlowClient(t *testing.T) {
+	server := mcp.NewServer("sse-backpressure-test", "1.0.0")
+	server.EnableWebTransport(mcp.WebConfig{Port: 8107, Host: "localhost"})
+	server.EnableSSE(mcp.DefaultSSEConfig())
+
+	require.NoError(t, server.StartWebTransport())
+	time.Sleep(100 * time.Millisecond)
+	defer func() { assert.NoError(t, server.StopWebTransport()) }()
+
+	// Slow client: connects but never reads its body, so its receive
+	// window eventually closes and the server's write to it blocks.
+	slowResp, err := http.Get("http://localhost:8107/events")
+	require.NoError(t, err)
+	defer slowResp.Body.Close()
+
+	// Fast client: drains everything as it arrives.
+	fastResp, err := http.Get("http://localhost:8107/events")
+	require.NoError(t, err)
+	defer fastResp.Body.Close()
+
+	var fastCount int32
+	go func() {
+		reader := bufio.NewReader(fastResp.Body)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if strings.HasPrefix(line, "data: ") {
+				atomic.AddInt32(&fastCount, 1)
+			}
+		}
+	}()
+
+	const numEvents = 200
+	payload := strings.Repeat("x", 64*1024)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < numEvents; i++ {
+			server.BroadcastSSEEvent(mcp.SSEEvent{Data: payload})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("BroadcastSSEEvent blocked on a slow client instead of dropping its backlog")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && atomic.LoadInt32(&fastCount) == 0 {
+		time.Sleep(20 * time.Millisecond)
+	}
+	assert.Greater(t, int(atomic.LoadInt32(&fastCount)), 0, "fast client never received any broadcast events")
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcp "github.com/can-acar/jarvis-mcp-sdk"
+)
+
+// upperCaseCodec is a throwaway binary Codec (JSON wrapped in an
+// upper-cased marker so round-tripping through it is distinguishable from
+// plain JSON) used to exercise codec negotiation without depending on the
+// build-tagged msgpack/cbor codecs.
+type upperCaseCodec struct{}
+
+func (upperCaseCodec) Marshal(v any) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.ToUpper(string(raw))), nil
+}
+
+func (upperCaseCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal([]byte(strings.ToLower(string(data))), v)
+}
+
+func (upperCaseCodec) ContentType() string { return "application/test-upper" }
+
+func init() {
+	mcp.RegisterCodec("upper", upperCaseCodec{}, true)
+}
+
+// TestSSECodecRoundTrip covers the ?codec= query parameter: a registered
+// binary codec's output must reach the client base64-encoded (it can't be
+// embedded as-is in a single-line "data:" field) and decode back to the
+// original event data, which previously had no test coverage at all.
+func TestSSECodecRoundTrip(t *testing.T) {
+	server := mcp.NewServer("sse-codec-test", "1.0.0")
+	server.EnableWebTransport(mcp.WebConfig{Port: 8104, Host: "localhost"})
+	server.EnableSSE(mcp.DefaultSSEConfig())
+
+	require.NoError(t, server.StartWebTransport())
+	time.Sleep(100 * time.Millisecond)
+	defer func() { assert.NoError(t, server.StopWebTransport()) }()
+
+	resp, err := http.Get("http://localhost:8104/events?codec=upper")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "application/test-upper", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+
+	server.BroadcastSSEEvent(mcp.SSEEvent{Data: map[string]interface{}{"hello": "world"}})
+
+	// The "connected" event fires first; keep reading until the broadcast
+	// event (the one with a "hello" key) arrives.
+	var decoded map[string]interface{}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		dataLine := strings.TrimSpace(strings.TrimPrefix(line, "data: "))
+
+		raw, err := base64.StdEncoding.DecodeString(dataLine)
+		require.NoError(t, err, "codec output must be base64-encoded on the wire")
+		require.True(t, strings.Contains(string(raw), "HELLO") || strings.Contains(string(raw), "STATUS"),
+			"expected upper-cased codec output, got %q", raw)
+
+		var event map[string]interface{}
+		require.NoError(t, upperCaseCodec{}.Unmarshal(raw, &event))
+		if _, ok := event["hello"]; ok {
+			decoded = event
+			break
+		}
+	}
+	require.NotNil(t, decoded, "never saw the broadcast event")
+	assert.Equal(t, fmt.Sprintf("%v", "world"), decoded["hello"])
+}
@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"bufio"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcp "github.com/can-acar/jarvis-mcp-sdk"
+)
+
+// TestSSEZeroHeartbeatIntervalDoesNotPanic is a regression test: EnableSSE
+// used to pass an unvalidated HeartbeatInterval straight to
+// time.NewTicker, so a perfectly reasonable SSEConfig{ReplayBufferSize: N}
+// (HeartbeatInterval left at its zero value) panicked on the first
+// /events request. EnableSSE now defaults it the same way every other
+// zero-value-sensitive config in this series is defaulted.
+func TestSSEZeroHeartbeatIntervalDoesNotPanic(t *testing.T) {
+	server := mcp.NewServer("sse-config-test", "1.0.0")
+	server.EnableWebTransport(mcp.WebConfig{Port: 8106, Host: "localhost"})
+	server.EnableSSE(mcp.SSEConfig{ReplayBufferSize: 10})
+
+	require.NoError(t, server.StartWebTransport())
+	time.Sleep(100 * time.Millisecond)
+	defer func() { assert.NoError(t, server.StopWebTransport()) }()
+
+	resp, err := http.Get("http://localhost:8106/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(line, "retry: "))
+
+	// Drain the "connected" event, which the handler writes before ever
+	// touching HeartbeatInterval: "id: N", "event: connected", "data: ...",
+	// and a blank line terminating it.
+	for i := 0; i < 4; i++ {
+		_, err := reader.ReadString('\n')
+		require.NoError(t, err)
+	}
+
+	// Creating the heartbeat ticker is the next thing the handler does. On
+	// the pre-fix code that panics, and net/http recovers by closing the
+	// connection, so a further read would return EOF almost immediately.
+	// Confirm it instead blocks (waiting on the next real event or
+	// heartbeat), meaning the connection is still alive.
+	result := make(chan error, 1)
+	go func() {
+		_, err := reader.ReadString('\n')
+		result <- err
+	}()
+	select {
+	case err := <-result:
+		t.Fatalf("connection closed unexpectedly (panic?): %v", err)
+	case <-time.After(300 * time.Millisecond):
+		// Still alive, as expected.
+	}
+}
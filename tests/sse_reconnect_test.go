@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcp "github.com/can-acar/jarvis-mcp-sdk"
+)
+
+// readSSEIDs reads "id: N" lines off an SSE response body until deadline,
+// returning every sequence number seen (including duplicates, if any).
+func readSSEIDs(t *testing.T, body *bufio.Reader, deadline time.Time, want int) []int64 {
+	t.Helper()
+	var ids []int64
+	type lineResult struct {
+		line string
+		err  error
+	}
+	lines := make(chan lineResult, 1)
+
+	for len(ids) < want && time.Now().Before(deadline) {
+		go func() {
+			line, err := body.ReadString('\n')
+			lines <- lineResult{line, err}
+		}()
+		select {
+		case res := <-lines:
+			if res.err != nil {
+				return ids
+			}
+			if strings.HasPrefix(res.line, "id: ") {
+				n, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(res.line, "id: ")), 10, 64)
+				if err == nil {
+					ids = append(ids, n)
+				}
+			}
+		case <-time.After(time.Until(deadline)):
+			return ids
+		}
+	}
+	return ids
+}
+
+// TestSSEReconnectReplayNoDuplicates is a regression test: broadcast()
+// appended to the replay buffer and fanned out to registered clients under
+// one lock, while handleSSE read the buffer for replay under a second,
+// later lock acquired after register(). An event broadcast in that gap
+// landed in both the replay batch and the client's live channel, so a
+// reconnecting client with Last-Event-ID set could see it twice.
+func TestSSEReconnectReplayNoDuplicates(t *testing.T) {
+	server := mcp.NewServer("sse-reconnect-test", "1.0.0")
+	server.EnableWebTransport(mcp.WebConfig{Port: 8100, Host: "localhost"})
+	server.EnableSSE(mcp.DefaultSSEConfig())
+
+	require.NoError(t, server.StartWebTransport())
+	time.Sleep(100 * time.Millisecond)
+	defer func() { assert.NoError(t, server.StopWebTransport()) }()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	// Several tight broadcaster goroutines maximize the odds that some
+	// broadcast lands inside the (otherwise very narrow) gap between a
+	// reconnecting client's registration and its replay read.
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				server.BroadcastSSEEvent(mcp.SSEEvent{Data: fmt.Sprintf("worker %d event %d", worker, i)})
+			}
+		}(w)
+	}
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	for attempt := 0; attempt < 40; attempt++ {
+		req, err := http.NewRequest("GET", "http://localhost:8100/events?lastEventId=1", nil)
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+
+		ids := readSSEIDs(t, bufio.NewReader(resp.Body), time.Now().Add(150*time.Millisecond), 10)
+		resp.Body.Close()
+
+		seen := make(map[int64]bool)
+		for _, id := range ids {
+			if seen[id] {
+				t.Fatalf("attempt %d: id %d delivered more than once in %v", attempt, id, ids)
+			}
+			seen[id] = true
+		}
+	}
+}
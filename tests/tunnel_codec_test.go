@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcp "github.com/can-acar/jarvis-mcp-sdk"
+)
+
+// jsonBinaryCodec is a throwaway Codec that exercises a connection
+// negotiating a binary codec subprotocol without depending on the
+// build-tagged msgpack/cbor codecs.
+type jsonBinaryCodec struct{}
+
+func (jsonBinaryCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonBinaryCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonBinaryCodec) ContentType() string                { return "application/test-binary" }
+
+const testBinarySubprotocol = "test.binary.v1"
+
+func init() {
+	mcp.RegisterCodec(testBinarySubprotocol, jsonBinaryCodec{}, true)
+}
+
+const tunnelMagicByte = 0xF7
+
+// encodeTestTunnelFrame mirrors the SDK's on-wire tunnel frame format: 1
+// magic byte, 4-byte big-endian stream id, 1 flag byte, then the payload.
+func encodeTestTunnelFrame(id uint32, flag byte, payload []byte) []byte {
+	buf := make([]byte, 6+len(payload))
+	buf[0] = tunnelMagicByte
+	binary.BigEndian.PutUint32(buf[1:5], id)
+	buf[5] = flag
+	copy(buf[6:], payload)
+	return buf
+}
+
+func decodeTestTunnelFrame(data []byte) (id uint32, flag byte, payload []byte, ok bool) {
+	if len(data) < 6 || data[0] != tunnelMagicByte {
+		return 0, 0, nil, false
+	}
+	return binary.BigEndian.Uint32(data[1:5]), data[5], data[6:], true
+}
+
+func mustTestJSON(t *testing.T, v interface{}) json.RawMessage {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
+
+// TestTunnelCoexistsWithBinaryCodec is a regression test: once a connection
+// negotiated a binary codec, every binary WebSocket frame on it (including
+// tunnel data frames) used to be fed straight to codec.Unmarshal as a
+// control message, silently breaking Tunnel on any such connection. Tunnel
+// frames now carry a leading magic byte so the two binary uses coexist.
+func TestTunnelCoexistsWithBinaryCodec(t *testing.T) {
+	server := mcp.NewServer("tunnel-codec-test", "1.0.0")
+	server.Tool("echo", "echoes", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{"ok": true}, nil
+	})
+
+	echoed := make(chan []byte, 1)
+	server.Tunnel("echo-tunnel", func(ctx context.Context, rw io.ReadWriter) error {
+		buf := make([]byte, 256)
+		n, err := rw.Read(buf)
+		if err != nil {
+			return err
+		}
+		echoed <- append([]byte(nil), buf[:n]...)
+		_, err = rw.Write(buf[:n])
+		return err
+	})
+
+	server.EnableWebTransport(mcp.WebConfig{Port: 8101, Host: "localhost"})
+	server.EnableWebSocket(mcp.DefaultWebSocketConfig())
+
+	require.NoError(t, server.StartWebTransport())
+	time.Sleep(100 * time.Millisecond)
+	defer func() { assert.NoError(t, server.StopWebTransport()) }()
+
+	dialer := websocket.Dialer{Subprotocols: []string{testBinarySubprotocol}}
+	u := url.URL{Scheme: "ws", Host: "localhost:8101", Path: "/ws"}
+	c, _, err := dialer.Dial(u.String(), nil)
+	require.NoError(t, err)
+	defer c.Close()
+	require.Equal(t, testBinarySubprotocol, c.Subprotocol())
+
+	// Open a tunnel on this same, binary-codec-negotiated connection.
+	openMsg := mcp.WebSocketMessage{Type: "tunnel_open", ID: "open-1", Params: mustTestJSON(t, map[string]string{"name": "echo-tunnel"})}
+	require.NoError(t, c.WriteJSON(openMsg))
+
+	var ready mcp.WebSocketMessage
+	require.NoError(t, c.ReadJSON(&ready))
+	require.Equal(t, "tunnel_ready", ready.Type)
+	result, ok := ready.Result.(map[string]interface{})
+	require.True(t, ok)
+	streamIDFloat, ok := result["streamId"].(float64)
+	require.True(t, ok)
+	streamID := uint32(streamIDFloat)
+
+	// Send a tunnel data frame; it must reach the tunnel handler instead of
+	// being misrouted into codec.Unmarshal as a control message.
+	payload := []byte("hello through the tunnel")
+	require.NoError(t, c.WriteMessage(websocket.BinaryMessage, encodeTestTunnelFrame(streamID, 0, payload)))
+
+	select {
+	case got := <-echoed:
+		assert.Equal(t, payload, got)
+	case <-time.After(2 * time.Second):
+		t.Fatal("tunnel handler never received the frame")
+	}
+
+	var reply []byte
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && reply == nil {
+		c.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+		msgType, data, err := c.ReadMessage()
+		require.NoError(t, err)
+		if msgType != websocket.BinaryMessage {
+			continue
+		}
+		if id, _, p, ok := decodeTestTunnelFrame(data); ok && id == streamID {
+			reply = p
+		}
+	}
+	assert.Equal(t, payload, reply)
+
+	// The connection's ordinary tools/call control path, framed through the
+	// binary codec, must still work alongside the tunnel.
+	callMsg := mcp.WebSocketMessage{
+		Type:   "request",
+		ID:     "call-1",
+		Method: "tools/call",
+		Params: mustTestJSON(t, map[string]interface{}{"name": "echo", "arguments": map[string]interface{}{}}),
+	}
+	require.NoError(t, c.WriteJSON(callMsg))
+
+	var response mcp.WebSocketMessage
+	deadline = time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		c.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+		msgType, data, err := c.ReadMessage()
+		require.NoError(t, err)
+		if msgType != websocket.BinaryMessage {
+			continue
+		}
+		if _, _, _, ok := decodeTestTunnelFrame(data); ok {
+			continue
+		}
+		require.NoError(t, json.Unmarshal(data, &response))
+		break
+	}
+	assert.Equal(t, "response", response.Type)
+	assert.Equal(t, "call-1", response.ID)
+}
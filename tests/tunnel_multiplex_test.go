@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcp "github.com/can-acar/jarvis-mcp-sdk"
+)
+
+// TestTunnelMultiplexesConcurrentStreams covers opening several tunnel
+// streams on one WebSocket connection at once: each stream's frames must
+// reach only its own handler, interleaved arbitrarily with the others, with
+// no cross-stream leakage. This had no coverage before.
+func TestTunnelMultiplexesConcurrentStreams(t *testing.T) {
+	server := mcp.NewServer("tunnel-multiplex-test", "1.0.0")
+
+	server.Tunnel("echo-tunnel", func(ctx context.Context, rw io.ReadWriter) error {
+		buf := make([]byte, 256)
+		for {
+			n, err := rw.Read(buf)
+			if err != nil {
+				return nil
+			}
+			if _, err := rw.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+	})
+
+	server.EnableWebTransport(mcp.WebConfig{Port: 8105, Host: "localhost"})
+	server.EnableWebSocket(mcp.DefaultWebSocketConfig())
+
+	require.NoError(t, server.StartWebTransport())
+	time.Sleep(100 * time.Millisecond)
+	defer func() { assert.NoError(t, server.StopWebTransport()) }()
+
+	u := url.URL{Scheme: "ws", Host: "localhost:8105", Path: "/ws"}
+	c, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	require.NoError(t, err)
+	defer c.Close()
+
+	const numStreams = 5
+
+	// Open every stream up front so their ids are assigned and in flight
+	// concurrently before any data is exchanged.
+	streamIDs := make([]uint32, numStreams)
+	for i := 0; i < numStreams; i++ {
+		openMsg := mcp.WebSocketMessage{
+			Type:   "tunnel_open",
+			ID:     "open",
+			Params: mustTestJSON(t, map[string]string{"name": "echo-tunnel"}),
+		}
+		require.NoError(t, c.WriteJSON(openMsg))
+
+		var ready mcp.WebSocketMessage
+		require.NoError(t, c.ReadJSON(&ready))
+		require.Equal(t, "tunnel_ready", ready.Type)
+		result, ok := ready.Result.(map[string]interface{})
+		require.True(t, ok)
+		streamIDFloat, ok := result["streamId"].(float64)
+		require.True(t, ok)
+		streamIDs[i] = uint32(streamIDFloat)
+	}
+
+	// A single reader goroutine demultiplexes incoming frames by stream id,
+	// since all streams share one underlying connection.
+	var mu sync.Mutex
+	received := make(map[uint32][][]byte)
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			c.SetReadDeadline(time.Now().Add(3 * time.Second))
+			msgType, data, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			if msgType != websocket.BinaryMessage {
+				continue
+			}
+			id, _, payload, ok := decodeTestTunnelFrame(data)
+			if !ok {
+				continue
+			}
+			mu.Lock()
+			received[id] = append(received[id], append([]byte(nil), payload...))
+			mu.Unlock()
+		}
+	}()
+
+	// Write one distinct payload per stream, interleaved, so a mux bug that
+	// routes a frame to the wrong stream is observable.
+	for i, id := range streamIDs {
+		payload := []byte{byte('a' + i)}
+		require.NoError(t, c.WriteMessage(websocket.BinaryMessage, encodeTestTunnelFrame(id, 0, payload)))
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		done := len(received) == numStreams
+		mu.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	close(stop)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, numStreams)
+	for i, id := range streamIDs {
+		want := []byte{byte('a' + i)}
+		assert.Equal(t, [][]byte{want}, received[id], "stream %d got the wrong payload, indicating cross-stream leakage", id)
+	}
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcp "github.com/can-acar/jarvis-mcp-sdk"
+)
+
+type staticPrincipal struct{ name string }
+
+func (p staticPrincipal) Name() string { return p.name }
+
+type headerAuthenticator struct{ expected string }
+
+func (a headerAuthenticator) Authenticate(r *http.Request) (mcp.Principal, error) {
+	if r.Header.Get("X-Api-Key") != a.expected {
+		return nil, errors.New("bad api key")
+	}
+	return staticPrincipal{name: "api-key-user"}, nil
+}
+
+// TestWebSocketLifecycleHooksAndAuthenticator covers OnConnect/OnDisconnect,
+// ConnInfo attributes, a pluggable Authenticator, and
+// PrincipalFromContext — none of which had any coverage before.
+func TestWebSocketLifecycleHooksAndAuthenticator(t *testing.T) {
+	server := mcp.NewServer("lifecycle-test", "1.0.0")
+
+	var mu sync.Mutex
+	var connected, disconnected []string
+	principalFromConnectCtx := ""
+
+	wsConfig := mcp.DefaultWebSocketConfig()
+	wsConfig.Authenticator = headerAuthenticator{expected: "right-key"}
+	wsConfig.OnConnect = func(ctx context.Context, info *mcp.ConnInfo) error {
+		mu.Lock()
+		connected = append(connected, info.Principal.Name())
+		mu.Unlock()
+		info.SetAttribute("greeting", "hello")
+		if p, ok := mcp.PrincipalFromContext(ctx); ok {
+			principalFromConnectCtx = p.Name()
+		}
+		return nil
+	}
+	wsConfig.OnDisconnect = func(info *mcp.ConnInfo, err error) {
+		mu.Lock()
+		disconnected = append(disconnected, info.Principal.Name())
+		mu.Unlock()
+	}
+
+	server.EnableWebTransport(mcp.WebConfig{Port: 8102, Host: "localhost"})
+	server.EnableWebSocket(wsConfig)
+
+	require.NoError(t, server.StartWebTransport())
+	time.Sleep(100 * time.Millisecond)
+	defer func() { assert.NoError(t, server.StopWebTransport()) }()
+
+	u := url.URL{Scheme: "ws", Host: "localhost:8102", Path: "/ws"}
+
+	t.Run("Authenticator rejects a bad key", func(t *testing.T) {
+		headers := http.Header{"X-Api-Key": []string{"wrong-key"}}
+		_, resp, err := websocket.DefaultDialer.Dial(u.String(), headers)
+		assert.Error(t, err)
+		if resp != nil {
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		}
+	})
+
+	t.Run("Authenticator accepts the right key and runs lifecycle hooks", func(t *testing.T) {
+		headers := http.Header{"X-Api-Key": []string{"right-key"}}
+		c, _, err := websocket.DefaultDialer.Dial(u.String(), headers)
+		require.NoError(t, err)
+
+		require.NoError(t, c.WriteJSON(mcp.WebSocketMessage{Type: "ping", ID: "p1"}))
+		var pong mcp.WebSocketMessage
+		require.NoError(t, c.ReadJSON(&pong))
+		assert.Equal(t, "pong", pong.Type)
+
+		c.Close()
+		time.Sleep(100 * time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Contains(t, connected, "api-key-user")
+		assert.Contains(t, disconnected, "api-key-user")
+		assert.Equal(t, "api-key-user", principalFromConnectCtx)
+	})
+}
+
+// TestTokenPrincipalNameDoesNotLeakToken is a regression test: the built-in
+// bearer-token Principal used to return "token:<raw secret>" from Name(),
+// which is documented as a human-readable/audit-log identifier — handing
+// every caller a ready-made credential leak.
+func TestTokenPrincipalNameDoesNotLeakToken(t *testing.T) {
+	server := mcp.NewServer("token-principal-test", "1.0.0")
+
+	var name string
+	wsConfig := mcp.DefaultWebSocketConfig()
+	wsConfig.OnConnect = func(ctx context.Context, info *mcp.ConnInfo) error {
+		name = info.Principal.Name()
+		return nil
+	}
+
+	server.EnableWebTransport(mcp.WebConfig{Port: 8103, Host: "localhost", AuthToken: "super-secret-token"})
+	server.EnableWebSocket(wsConfig)
+
+	require.NoError(t, server.StartWebTransport())
+	time.Sleep(100 * time.Millisecond)
+	defer func() { assert.NoError(t, server.StopWebTransport()) }()
+
+	u := url.URL{Scheme: "ws", Host: "localhost:8103", Path: "/ws", RawQuery: "token=super-secret-token"}
+	c, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.NoError(t, c.WriteJSON(mcp.WebSocketMessage{Type: "ping", ID: "p1"}))
+	var pong mcp.WebSocketMessage
+	require.NoError(t, c.ReadJSON(&pong))
+
+	assert.NotContains(t, name, "super-secret-token")
+	assert.False(t, strings.HasPrefix(name, "token:"))
+}
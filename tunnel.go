@@ -0,0 +1,348 @@
+package mcp
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+)
+
+// TunnelHandler services one tunneled byte stream opened over a WebSocket
+// connection. rw reads bytes the remote end wrote and its writes are framed
+// back to the remote end. The handler should return once rw is exhausted
+// (Read returns io.EOF) or ctx is canceled.
+type TunnelHandler func(ctx context.Context, rw io.ReadWriter) error
+
+const (
+	tunnelFrameData byte = 0
+	tunnelFrameFIN  byte = 1
+
+	// tunnelMagicByte tags every tunnel frame so it can be told apart from a
+	// binary-codec-encoded control message: once a connection negotiates a
+	// binary codec (msgpack, CBOR), both uses share the same
+	// websocket.BinaryMessage frame type with nothing else to distinguish
+	// them by.
+	tunnelMagicByte byte = 0xF7
+)
+
+// encodeTunnelFrame lays out a binary WebSocket frame as: 1 magic byte,
+// 4-byte big-endian stream id, 1 flag byte, then the payload.
+func encodeTunnelFrame(id uint32, flag byte, payload []byte) []byte {
+	buf := make([]byte, 6+len(payload))
+	buf[0] = tunnelMagicByte
+	binary.BigEndian.PutUint32(buf[1:5], id)
+	buf[5] = flag
+	copy(buf[6:], payload)
+	return buf
+}
+
+// decodeTunnelFrame reports ok=false for anything that isn't a tunnel frame
+// (too short, or missing the magic byte), which is also how the read loop
+// tells a tunnel frame apart from a binary-codec control message sharing the
+// same WebSocket frame type.
+func decodeTunnelFrame(data []byte) (id uint32, flag byte, payload []byte, ok bool) {
+	if len(data) < 6 || data[0] != tunnelMagicByte {
+		return 0, 0, nil, false
+	}
+	return binary.BigEndian.Uint32(data[1:5]), data[5], data[6:], true
+}
+
+// tunnelStream is the server-side io.ReadWriter handed to a TunnelHandler.
+// Incoming frames tagged with its id are delivered on incoming; writes are
+// framed back out over the owning connection.
+type tunnelStream struct {
+	id   uint32
+	conn *wsConn
+
+	incoming chan []byte
+	leftover []byte
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newTunnelStream(id uint32, conn *wsConn) *tunnelStream {
+	return &tunnelStream{
+		id:       id,
+		conn:     conn,
+		incoming: make(chan []byte, 16),
+		closed:   make(chan struct{}),
+	}
+}
+
+func (t *tunnelStream) Read(p []byte) (int, error) {
+	if len(t.leftover) > 0 {
+		n := copy(p, t.leftover)
+		t.leftover = t.leftover[n:]
+		return n, nil
+	}
+	select {
+	case data, ok := <-t.incoming:
+		if !ok {
+			return 0, io.EOF
+		}
+		n := copy(p, data)
+		if n < len(data) {
+			t.leftover = data[n:]
+		}
+		return n, nil
+	case <-t.closed:
+		return 0, io.EOF
+	}
+}
+
+func (t *tunnelStream) Write(p []byte) (int, error) {
+	if err := t.conn.writeBinary(encodeTunnelFrame(t.id, tunnelFrameData, p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// deliver feeds a frame's payload to the handler side, or closes the stream
+// on a FIN frame.
+func (t *tunnelStream) deliver(flag byte, payload []byte) {
+	if flag == tunnelFrameFIN {
+		t.closeOnce.Do(func() { close(t.closed) })
+		return
+	}
+	select {
+	case t.incoming <- payload:
+	case <-t.closed:
+	}
+}
+
+func (t *tunnelStream) fin() {
+	t.closeOnce.Do(func() { close(t.closed) })
+}
+
+// writeBinary queues a binary frame on the connection's write pump, the
+// same path used for control messages, so tunnel data and control traffic
+// never race on the underlying conn.
+func (w *wsConn) writeBinary(data []byte) error {
+	w.enqueue(outboundFrame{frameType: websocket.BinaryMessage, data: data})
+	return nil
+}
+
+// Tunnel registers a named tunnel handler. Clients open it by sending a
+// tunnel_open message naming it; the server then pipes binary WebSocket
+// frames to and from handler's ReadWriter.
+func (s *Server) Tunnel(name string, handler TunnelHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tunnels[name] = handler
+}
+
+func (s *Server) tunnelHandler(name string) (TunnelHandler, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	h, ok := s.tunnels[name]
+	return h, ok
+}
+
+func (s *Server) handleTunnelOpen(ctx context.Context, conn *wsConn, info *ConnInfo, msg WebSocketMessage) {
+	var params struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		conn.writeJSON(errorMessage(msg.ID, "invalid params: "+err.Error()))
+		return
+	}
+
+	handler, ok := s.tunnelHandler(params.Name)
+	if !ok {
+		conn.writeJSON(errorMessage(msg.ID, "unknown tunnel: "+params.Name))
+		return
+	}
+
+	streamID := info.nextTunnelStreamID()
+	stream := newTunnelStream(streamID, conn)
+	info.registerTunnelStream(stream)
+
+	conn.writeJSON(WebSocketMessage{
+		Type:   "tunnel_ready",
+		ID:     msg.ID,
+		Result: map[string]interface{}{"name": params.Name, "streamId": streamID},
+	})
+
+	go func() {
+		defer info.unregisterTunnelStream(streamID)
+		defer conn.writeBinary(encodeTunnelFrame(streamID, tunnelFrameFIN, nil))
+		_ = handler(ctx, stream)
+	}()
+}
+
+// nextTunnelStreamID and the tunnel stream registry live on ConnInfo so that
+// multiple tunnels can be multiplexed over one WebSocket connection.
+func (c *ConnInfo) nextTunnelStreamID() uint32 {
+	return uint32(atomic.AddUint32(&c.tunnelSeq, 1))
+}
+
+func (c *ConnInfo) registerTunnelStream(t *tunnelStream) {
+	c.tunnelMu.Lock()
+	defer c.tunnelMu.Unlock()
+	if c.tunnelStreams == nil {
+		c.tunnelStreams = make(map[uint32]*tunnelStream)
+	}
+	c.tunnelStreams[t.id] = t
+}
+
+func (c *ConnInfo) unregisterTunnelStream(id uint32) {
+	c.tunnelMu.Lock()
+	defer c.tunnelMu.Unlock()
+	delete(c.tunnelStreams, id)
+}
+
+func (c *ConnInfo) tunnelStream(id uint32) (*tunnelStream, bool) {
+	c.tunnelMu.Lock()
+	defer c.tunnelMu.Unlock()
+	t, ok := c.tunnelStreams[id]
+	return t, ok
+}
+
+// dispatchTunnelFrame routes an incoming binary frame to its tunnelStream.
+func (s *Server) dispatchTunnelFrame(info *ConnInfo, data []byte) {
+	id, flag, payload, ok := decodeTunnelFrame(data)
+	if !ok {
+		return
+	}
+	if stream, ok := info.tunnelStream(id); ok {
+		stream.deliver(flag, payload)
+	}
+}
+
+// closeAllTunnelStreams is called once a connection's read loop exits, so
+// in-flight TunnelHandlers observe EOF instead of blocking forever.
+func (c *ConnInfo) closeAllTunnelStreams() {
+	c.tunnelMu.Lock()
+	defer c.tunnelMu.Unlock()
+	for _, t := range c.tunnelStreams {
+		t.fin()
+	}
+}
+
+// DialTunnel opens a named tunnel on an MCP WebSocket server and returns a
+// bidirectional stream for it, suitable for e.g. io.Copy(conn, os.Stdin).
+func DialTunnel(wsURL, name, token string) (io.ReadWriteCloser, error) {
+	u, err := url.Parse(wsURL)
+	if err != nil {
+		return nil, fmt.Errorf("mcp: parse tunnel url: %w", err)
+	}
+
+	var header http.Header
+	if token != "" {
+		header = http.Header{"Authorization": []string{"Bearer " + token}}
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), header)
+	if err != nil {
+		return nil, fmt.Errorf("mcp: dial tunnel: %w", err)
+	}
+
+	openMsg := WebSocketMessage{Type: "tunnel_open", ID: "tunnel-open", Params: mustMarshal(map[string]string{"name": name})}
+	if err := conn.WriteJSON(openMsg); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("mcp: send tunnel_open: %w", err)
+	}
+
+	var ready WebSocketMessage
+	if err := conn.ReadJSON(&ready); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("mcp: read tunnel_ready: %w", err)
+	}
+	if ready.Type == "error" {
+		conn.Close()
+		if ready.Error != nil {
+			return nil, fmt.Errorf("mcp: tunnel_open failed: %s", ready.Error.Message)
+		}
+		return nil, fmt.Errorf("mcp: tunnel_open failed")
+	}
+
+	result, ok := ready.Result.(map[string]interface{})
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("mcp: malformed tunnel_ready")
+	}
+	streamIDFloat, ok := result["streamId"].(float64)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("mcp: tunnel_ready missing streamId")
+	}
+
+	return newClientTunnelConn(conn, uint32(streamIDFloat)), nil
+}
+
+func mustMarshal(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// clientTunnelConn is the client-side counterpart of tunnelStream: it reads
+// binary frames off the shared WebSocket connection, keeping only the ones
+// tagged for its stream id.
+type clientTunnelConn struct {
+	conn     *websocket.Conn
+	streamID uint32
+
+	writeMu  sync.Mutex
+	leftover []byte
+	closed   chan struct{}
+}
+
+func newClientTunnelConn(conn *websocket.Conn, streamID uint32) *clientTunnelConn {
+	return &clientTunnelConn{conn: conn, streamID: streamID, closed: make(chan struct{})}
+}
+
+func (c *clientTunnelConn) Read(p []byte) (int, error) {
+	if len(c.leftover) > 0 {
+		n := copy(p, c.leftover)
+		c.leftover = c.leftover[n:]
+		return n, nil
+	}
+	for {
+		msgType, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return 0, err
+		}
+		if msgType != websocket.BinaryMessage {
+			continue
+		}
+		id, flag, payload, ok := decodeTunnelFrame(data)
+		if !ok || id != c.streamID {
+			continue
+		}
+		if flag == tunnelFrameFIN {
+			return 0, io.EOF
+		}
+		n := copy(p, payload)
+		if n < len(payload) {
+			c.leftover = payload[n:]
+		}
+		return n, nil
+	}
+}
+
+func (c *clientTunnelConn) Write(p []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.conn.WriteMessage(websocket.BinaryMessage, encodeTunnelFrame(c.streamID, tunnelFrameData, p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *clientTunnelConn) Close() error {
+	c.writeMu.Lock()
+	_ = c.conn.WriteMessage(websocket.BinaryMessage, encodeTunnelFrame(c.streamID, tunnelFrameFIN, nil))
+	c.writeMu.Unlock()
+	return c.conn.Close()
+}
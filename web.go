@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WebConfig configures the shared HTTP listener that the SSE and WebSocket
+// transports are mounted on.
+type WebConfig struct {
+	Host string
+	Port int
+
+	// AuthToken, when set, is required on every request to the web
+	// transport: either as a "token" query parameter or as an
+	// "Authorization: Bearer <token>" header. On /ws, a configured
+	// WebSocketConfig.Authenticator replaces this check.
+	AuthToken string
+}
+
+// EnableWebTransport configures the HTTP listener used by SSE/WebSocket. It
+// must be called before EnableSSE/EnableWebSocket and StartWebTransport.
+func (s *Server) EnableWebTransport(cfg WebConfig) {
+	s.webMu.Lock()
+	defer s.webMu.Unlock()
+	s.webConfig = cfg
+	s.mux = http.NewServeMux()
+}
+
+// StartWebTransport starts listening for HTTP connections using the
+// previously configured transports (SSE, WebSocket).
+func (s *Server) StartWebTransport() error {
+	s.webMu.Lock()
+	defer s.webMu.Unlock()
+
+	if s.mux == nil {
+		return fmt.Errorf("mcp: EnableWebTransport must be called before StartWebTransport")
+	}
+
+	addr := fmt.Sprintf("%s:%d", s.webConfig.Host, s.webConfig.Port)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("mcp: listen on %s: %w", addr, err)
+	}
+
+	s.httpServer = &http.Server{Handler: s.mux}
+	s.listener = listener
+
+	go func() {
+		_ = s.httpServer.Serve(listener)
+	}()
+
+	return nil
+}
+
+// StopWebTransport gracefully shuts down the HTTP listener started by
+// StartWebTransport.
+func (s *Server) StopWebTransport() error {
+	s.webMu.Lock()
+	httpServer := s.httpServer
+	s.webMu.Unlock()
+
+	if httpServer == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return httpServer.Shutdown(ctx)
+}
+
+// checkAuth enforces WebConfig.AuthToken (if set) against the "token" query
+// parameter or a "Authorization: Bearer <token>" header. It returns true if
+// the request is authorized.
+func (s *Server) checkAuth(r *http.Request) bool {
+	if s.webConfig.AuthToken == "" {
+		return true
+	}
+
+	if token := r.URL.Query().Get("token"); token == s.webConfig.AuthToken {
+		return true
+	}
+
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		if strings.TrimPrefix(auth, "Bearer ") == s.webConfig.AuthToken {
+			return true
+		}
+	}
+
+	return false
+}
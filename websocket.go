@@ -0,0 +1,373 @@
+package mcp
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketConfig configures the WebSocket transport.
+type WebSocketConfig struct {
+	ReadBufferSize  int
+	WriteBufferSize int
+
+	// Subprotocols advertised to clients during the upgrade handshake.
+	Subprotocols []string
+
+	// Authenticator, when set, replaces the static bearer token check
+	// (WebConfig.AuthToken) for authenticating upgrade requests.
+	Authenticator Authenticator
+
+	// OnConnect is called once a connection has been upgraded and
+	// authenticated, before any messages are read. Returning an error
+	// rejects the connection and it is closed immediately.
+	OnConnect func(ctx context.Context, info *ConnInfo) error
+
+	// OnDisconnect is called when a connection's read loop exits, with the
+	// error (if any) that caused it to exit.
+	OnDisconnect func(info *ConnInfo, err error)
+
+	// OnMessage is called for every WebSocketMessage decoded from a
+	// FramingCustom connection, before it is dispatched. It is not called
+	// for tunnel frames, for frames that fail to decode, or for JSON-RPC
+	// 2.0 frames. It is an observation hook and cannot alter or suppress
+	// dispatch.
+	OnMessage func(info *ConnInfo, msg WebSocketMessage)
+
+	// Framing selects the wire format: FramingCustom (default) for the
+	// bespoke WebSocketMessage envelope, or FramingJSONRPC2 for JSON-RPC
+	// 2.0 with batching and $/cancel. A connection negotiating the
+	// SubprotocolJSONRPC2 subprotocol always gets FramingJSONRPC2
+	// regardless of this setting.
+	Framing FramingMode
+
+	// MaxBatchConcurrency bounds how many requests from one JSON-RPC batch
+	// run concurrently. Ignored outside FramingJSONRPC2. Defaults to 8.
+	MaxBatchConcurrency int
+
+	// Streaming bounds outbound queueing and concurrent subscriptions for
+	// streaming tools delivered over this connection.
+	Streaming StreamingConfig
+}
+
+// DefaultWebSocketConfig returns sane defaults for the WebSocket transport.
+func DefaultWebSocketConfig() WebSocketConfig {
+	return WebSocketConfig{
+		ReadBufferSize:      4096,
+		WriteBufferSize:     4096,
+		MaxBatchConcurrency: 8,
+		Streaming:           DefaultStreamingConfig(),
+	}
+}
+
+// ConnInfo describes a single upgraded WebSocket connection, available to
+// lifecycle hooks. Tool handlers invoked over the connection do not receive
+// it; they can read its Principal via PrincipalFromContext.
+type ConnInfo struct {
+	RemoteAddr  string
+	Subprotocol string
+	TLS         *tls.ConnectionState
+	Header      http.Header
+	Principal   Principal
+
+	attrMu     sync.Mutex
+	Attributes map[string]any
+
+	conn *wsConn
+
+	tunnelMu      sync.Mutex
+	tunnelSeq     uint32
+	tunnelStreams map[uint32]*tunnelStream
+
+	// Framing is the wire format negotiated for this connection.
+	Framing FramingMode
+
+	rpcMu      sync.Mutex
+	rpcCancels map[string]context.CancelFunc
+
+	streamMu   sync.Mutex
+	streamSubs map[string]*streamSubscription
+}
+
+// SetAttribute sets a key on Attributes under lock, for concurrent use by
+// lifecycle hooks and tool handlers sharing the same connection.
+func (c *ConnInfo) SetAttribute(key string, value any) {
+	c.attrMu.Lock()
+	defer c.attrMu.Unlock()
+	c.Attributes[key] = value
+}
+
+// Attribute reads a key from Attributes under lock.
+func (c *ConnInfo) Attribute(key string) (any, bool) {
+	c.attrMu.Lock()
+	defer c.attrMu.Unlock()
+	v, ok := c.Attributes[key]
+	return v, ok
+}
+
+// wsConn wraps a *websocket.Conn with a bounded outbound queue: gorilla's
+// Conn permits only one concurrent writer, but the read loop, streaming
+// producers, and tunnel handlers all need to write frames on the same
+// connection. A dedicated write pump goroutine (runWritePump) is the sole
+// caller of the underlying conn's WriteMessage; enqueue applies
+// streamingCfg.DropPolicy once the queue is full.
+type wsConn struct {
+	conn *websocket.Conn
+
+	// codec and binary select how writeJSON (and the read loop's control
+	// message decoding) encode/decode WebSocketMessage frames, per the
+	// subprotocol negotiated during upgrade. Defaults to JSON over text
+	// frames.
+	codec  Codec
+	binary bool
+
+	outbound     chan outboundFrame
+	streamingCfg StreamingConfig
+	metrics      *WebSocketMetrics
+	closeOnce    sync.Once
+	closed       chan struct{}
+}
+
+func newWSConn(raw *websocket.Conn, cfg StreamingConfig, metrics *WebSocketMetrics) *wsConn {
+	if cfg.PerClientOutboundQueue <= 0 {
+		cfg.PerClientOutboundQueue = 64
+	}
+	return &wsConn{
+		conn:         raw,
+		codec:        jsonCodec{},
+		outbound:     make(chan outboundFrame, cfg.PerClientOutboundQueue),
+		streamingCfg: cfg,
+		metrics:      metrics,
+		closed:       make(chan struct{}),
+	}
+}
+
+// close tears the connection down without sending a close frame of its own
+// (the read loop's ReadMessage error already means the peer is gone, or
+// we're the one hanging up after OnConnect rejects it). Safe to call more
+// than once; shutdown (shared with closeSlow) guards against that.
+func (w *wsConn) close() {
+	w.shutdown(false, 0, "")
+}
+
+func (w *wsConn) writeJSON(v interface{}) error {
+	data, err := w.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	frameType := websocket.TextMessage
+	if w.binary {
+		frameType = websocket.BinaryMessage
+	}
+	w.enqueue(outboundFrame{frameType: frameType, data: data})
+	return nil
+}
+
+// WSError is the error payload carried on a WebSocketMessage of type
+// "error".
+type WSError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// WebSocketMessage is the envelope used by the WebSocket transport's default
+// framing: type/id/method/params for requests, with result/error on
+// responses.
+type WebSocketMessage struct {
+	Type      string          `json:"type"`
+	ID        string          `json:"id,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	Result    interface{}     `json:"result,omitempty"`
+	Error     *WSError        `json:"error,omitempty"`
+	Timestamp int64           `json:"timestamp,omitempty"`
+}
+
+// EnableWebSocket mounts the WebSocket transport's /ws endpoint on the web
+// transport's mux. EnableWebTransport must be called first.
+func (s *Server) EnableWebSocket(cfg WebSocketConfig) {
+	s.webMu.Lock()
+	s.wsConfig = cfg
+	s.wsMetrics = &WebSocketMetrics{}
+	s.mux.HandleFunc("/ws", s.handleWS)
+	s.webMu.Unlock()
+}
+
+func (s *Server) authenticateWS(r *http.Request) (Principal, error) {
+	if s.wsConfig.Authenticator != nil {
+		return s.wsConfig.Authenticator.Authenticate(r)
+	}
+	if s.webConfig.AuthToken == "" {
+		return nil, nil
+	}
+	if !s.checkAuth(r) {
+		return nil, errUnauthorized
+	}
+	return tokenPrincipal{token: s.webConfig.AuthToken}, nil
+}
+
+func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
+	principal, err := s.authenticateWS(r)
+	if err != nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	subprotocols := append([]string{}, s.wsConfig.Subprotocols...)
+	subprotocols = append(subprotocols, SubprotocolJSONRPC2)
+	subprotocols = append(subprotocols, registeredSubprotocols()...)
+
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  s.wsConfig.ReadBufferSize,
+		WriteBufferSize: s.wsConfig.WriteBufferSize,
+		Subprotocols:    subprotocols,
+		CheckOrigin:     func(r *http.Request) bool { return true },
+	}
+
+	rawConn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn := newWSConn(rawConn, s.wsConfig.Streaming, s.wsMetrics)
+	if reg, ok := getCodec(rawConn.Subprotocol()); ok {
+		conn.codec = reg.codec
+		conn.binary = reg.binary
+	}
+	go conn.runWritePump()
+
+	framing := s.wsConfig.Framing
+	if rawConn.Subprotocol() == SubprotocolJSONRPC2 {
+		framing = FramingJSONRPC2
+	}
+
+	info := &ConnInfo{
+		RemoteAddr:  r.RemoteAddr,
+		Subprotocol: rawConn.Subprotocol(),
+		TLS:         r.TLS,
+		Header:      r.Header,
+		Principal:   principal,
+		Attributes:  make(map[string]any),
+		conn:        conn,
+		Framing:     framing,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = contextWithPrincipal(ctx, principal)
+
+	if s.wsConfig.OnConnect != nil {
+		if err := s.wsConfig.OnConnect(ctx, info); err != nil {
+			conn.close()
+			return
+		}
+	}
+
+	loopErr := s.wsReadLoop(ctx, conn, info)
+
+	if s.wsConfig.OnDisconnect != nil {
+		s.wsConfig.OnDisconnect(info, loopErr)
+	}
+}
+
+func (s *Server) wsReadLoop(ctx context.Context, conn *wsConn, info *ConnInfo) error {
+	defer conn.close()
+	defer info.closeAllTunnelStreams()
+	defer info.closeAllStreamSubscriptions()
+
+	for {
+		msgType, data, err := conn.conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		if msgType == websocket.BinaryMessage {
+			if _, _, _, ok := decodeTunnelFrame(data); ok {
+				s.dispatchTunnelFrame(info, data)
+				continue
+			}
+			if !conn.binary {
+				// Not a tunnel frame and no binary codec negotiated: nothing
+				// on this connection should be producing binary frames, so
+				// there's nothing sensible to dispatch it to.
+				continue
+			}
+			// Falls through to the binary-codec-encoded control message
+			// handling below.
+		}
+
+		if info.Framing == FramingJSONRPC2 {
+			s.handleJSONRPC2Frame(ctx, conn, info, data)
+			continue
+		}
+
+		var msg WebSocketMessage
+		if err := conn.codec.Unmarshal(data, &msg); err != nil {
+			conn.writeJSON(errorMessage("", "invalid message: "+err.Error()))
+			continue
+		}
+
+		if s.wsConfig.OnMessage != nil {
+			s.wsConfig.OnMessage(info, msg)
+		}
+
+		s.dispatchWS(ctx, conn, info, msg)
+	}
+}
+
+func (s *Server) dispatchWS(ctx context.Context, conn *wsConn, info *ConnInfo, msg WebSocketMessage) {
+	switch msg.Type {
+	case "ping":
+		conn.writeJSON(WebSocketMessage{Type: "pong", ID: msg.ID, Timestamp: time.Now().Unix()})
+
+	case "request":
+		if msg.Method == "" {
+			conn.writeJSON(errorMessage(msg.ID, "Method is required"))
+			return
+		}
+		s.dispatchWSRequest(ctx, conn, info, msg)
+
+	case "tunnel_open":
+		s.handleTunnelOpen(ctx, conn, info, msg)
+
+	case "stream_cancel":
+		s.handleStreamCancel(info, msg)
+
+	default:
+		conn.writeJSON(errorMessage(msg.ID, "Unknown message type: "+msg.Type))
+	}
+}
+
+func (s *Server) dispatchWSRequest(ctx context.Context, conn *wsConn, info *ConnInfo, msg WebSocketMessage) {
+	switch msg.Method {
+	case "tools/call":
+		var call struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(msg.Params, &call); err != nil {
+			conn.writeJSON(errorMessage(msg.ID, "invalid params: "+err.Error()))
+			return
+		}
+		result, err := s.CallTool(ctx, call.Name, call.Arguments)
+		if err != nil {
+			conn.writeJSON(errorMessage(msg.ID, err.Error()))
+			return
+		}
+		conn.writeJSON(WebSocketMessage{Type: "response", ID: msg.ID, Result: result})
+
+	case "stream/subscribe":
+		s.handleStreamSubscribe(ctx, conn, info, msg)
+
+	default:
+		conn.writeJSON(errorMessage(msg.ID, "Unknown method: "+msg.Method))
+	}
+}
+
+func errorMessage(id, message string) WebSocketMessage {
+	return WebSocketMessage{Type: "error", ID: id, Error: &WSError{Code: 400, Message: message}}
+}
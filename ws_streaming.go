@@ -0,0 +1,133 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+)
+
+type streamSubscription struct {
+	cancel context.CancelFunc
+}
+
+// handleStreamSubscribe services a "stream/subscribe" request by invoking
+// the named streaming tool and forwarding each StreamingResult to the
+// client as a "stream_update" message, finishing with the result's
+// Finished flag. The subscription can be ended early either by the client
+// sending a matching "stream_cancel" or by the connection closing.
+func (s *Server) handleStreamSubscribe(ctx context.Context, conn *wsConn, info *ConnInfo, msg WebSocketMessage) {
+	var sub struct {
+		Tool      string          `json:"tool"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(msg.Params, &sub); err != nil {
+		conn.writeJSON(errorMessage(msg.ID, "invalid params: "+err.Error()))
+		return
+	}
+
+	if !info.acquireStreamSlot(conn.streamingCfg.MaxStreamsPerConn) {
+		conn.writeJSON(errorMessage(msg.ID, "too many active streams on this connection"))
+		return
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	info.registerStreamSubscription(msg.ID, cancel)
+
+	results, err := s.CallStreamingTool(streamCtx, sub.Tool, sub.Arguments)
+	if err != nil {
+		cancel()
+		info.unregisterStreamSubscription(msg.ID)
+		conn.writeJSON(errorMessage(msg.ID, err.Error()))
+		return
+	}
+
+	conn.writeJSON(WebSocketMessage{
+		Type:   "response",
+		ID:     msg.ID,
+		Result: map[string]interface{}{"subscribed": true},
+	})
+
+	if conn.metrics != nil {
+		atomic.AddInt64(&conn.metrics.streamsActive, 1)
+	}
+
+	go func() {
+		defer cancel()
+		defer info.unregisterStreamSubscription(msg.ID)
+		defer func() {
+			if conn.metrics != nil {
+				atomic.AddInt64(&conn.metrics.streamsActive, -1)
+			}
+		}()
+
+		for {
+			select {
+			case <-streamCtx.Done():
+				return
+			case result, ok := <-results:
+				if !ok {
+					return
+				}
+				conn.writeJSON(WebSocketMessage{
+					Type:   "stream_update",
+					ID:     msg.ID,
+					Result: result,
+				})
+				if result.Finished {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// handleStreamCancel ends the subscription identified by msg.ID, canceling
+// the context passed into its StreamingToolHandler so the producer stops
+// promptly instead of running to completion.
+func (s *Server) handleStreamCancel(info *ConnInfo, msg WebSocketMessage) {
+	info.cancelStreamSubscription(msg.ID)
+}
+
+func (c *ConnInfo) acquireStreamSlot(max int) bool {
+	if max <= 0 {
+		return true
+	}
+	c.streamMu.Lock()
+	defer c.streamMu.Unlock()
+	return len(c.streamSubs) < max
+}
+
+func (c *ConnInfo) registerStreamSubscription(id string, cancel context.CancelFunc) {
+	c.streamMu.Lock()
+	defer c.streamMu.Unlock()
+	if c.streamSubs == nil {
+		c.streamSubs = make(map[string]*streamSubscription)
+	}
+	c.streamSubs[id] = &streamSubscription{cancel: cancel}
+}
+
+func (c *ConnInfo) unregisterStreamSubscription(id string) {
+	c.streamMu.Lock()
+	defer c.streamMu.Unlock()
+	delete(c.streamSubs, id)
+}
+
+func (c *ConnInfo) cancelStreamSubscription(id string) {
+	c.streamMu.Lock()
+	sub, ok := c.streamSubs[id]
+	c.streamMu.Unlock()
+	if ok {
+		sub.cancel()
+	}
+}
+
+// closeAllStreamSubscriptions is called once a connection's read loop
+// exits, so every streaming tool producer still running on it is canceled
+// instead of leaking until it finishes on its own.
+func (c *ConnInfo) closeAllStreamSubscriptions() {
+	c.streamMu.Lock()
+	defer c.streamMu.Unlock()
+	for _, sub := range c.streamSubs {
+		sub.cancel()
+	}
+}